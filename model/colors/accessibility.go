@@ -0,0 +1,225 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package colors
+
+import (
+	"math"
+
+	"github.com/richardwilkes/unison"
+)
+
+// Profile names that can be passed to Colors.MakeCurrent. ProfileNormal uses each ThemedColor's Light/Dark pair
+// exactly as authored.
+const (
+	ProfileNormal       = ""
+	ProfileHighContrast = "high-contrast"
+	ProfileDeuteranopia = "deuteranopia"
+	ProfileProtanopia   = "protanopia"
+	ProfileTritanopia   = "tritanopia"
+)
+
+const (
+	normalTextContrastMinimum = 4.5
+	largeTextContrastMinimum  = 3.0
+	autoAdjustMaxIterations   = 64
+	autoAdjustLightnessStep   = 0.02
+)
+
+// ContrastIssue describes a ThemedColor pair whose contrast ratio doesn't meet WCAG 2.1 guidelines.
+type ContrastIssue struct {
+	ID              string
+	Variant         string // "light" or "dark"
+	Ratio           float64
+	MeetsLargeText  bool
+	MeetsNormalText bool
+}
+
+// ApplyProfile transforms a single color for the given accessibility profile. ProfileNormal (or any unrecognized
+// profile) returns the color unchanged.
+func ApplyProfile(profile string, clr unison.Color) unison.Color {
+	switch profile {
+	case ProfileHighContrast:
+		return pushLightnessToExtreme(clr)
+	case ProfileDeuteranopia, ProfileProtanopia, ProfileTritanopia:
+		return rotateHueAwayFromConfusionLine(profile, clr)
+	default:
+		return clr
+	}
+}
+
+// pushLightnessToExtreme nudges a color's OKLab lightness toward 0 or 1, whichever is closer, while leaving its hue
+// (the a/b chromaticity) untouched. This is the basis of the HighContrast profile.
+func pushLightnessToExtreme(clr unison.Color) unison.Color {
+	l, a, b := rgbToOKLab(clr)
+	if l < 0.5 {
+		l = math.Max(0, l-0.25)
+	} else {
+		l = math.Min(1, l+0.25)
+	}
+	return oklabToRGB(l, a, b)
+}
+
+// rotateHueAwayFromConfusionLine shifts a color's hue away from the pair of hues a given color-vision deficiency
+// confuses, so adjacent theme colors that differ mainly by hue on that confusion line become distinguishable by
+// lightness/saturation instead. This is a deliberately simple heuristic rather than a full dichromat simulation.
+func rotateHueAwayFromConfusionLine(profile string, clr unison.Color) unison.Color {
+	l, a, b := rgbToOKLab(clr)
+	chroma := math.Hypot(a, b)
+	if chroma < 0.01 {
+		return clr // Effectively gray; nothing to rotate.
+	}
+	hue := math.Atan2(b, a)
+	var rotation float64
+	switch profile {
+	case ProfileDeuteranopia, ProfileProtanopia:
+		rotation = math.Pi / 6 // Red/green confusion line: rotate toward the blue/yellow axis.
+	case ProfileTritanopia:
+		rotation = -math.Pi / 6 // Blue/yellow confusion line: rotate toward the red/green axis.
+	}
+	hue += rotation
+	chroma = math.Min(chroma*1.15, 0.4) // Boost saturation slightly so the shifted hue stays distinguishable.
+	return oklabToRGB(l, chroma*math.Cos(hue), chroma*math.Sin(hue))
+}
+
+// Validate computes the WCAG 2.1 contrast ratio for every one of this Colors' entries against its derived "on"
+// color, for both the light and dark variants, and returns the pairs that fall short of the minimums (4.5:1 for
+// normal text, 3:1 for large text).
+func (c *Colors) Validate() []ContrastIssue {
+	var issues []ContrastIssue
+	for id, clr := range c.data {
+		on := clr.DeriveOn()
+		for _, variant := range []struct {
+			name      string
+			surface   unison.Color
+			onSurface unison.Color
+		}{
+			{name: "light", surface: clr.Light, onSurface: on.Light},
+			{name: "dark", surface: clr.Dark, onSurface: on.Dark},
+		} {
+			ratio := contrastRatio(variant.surface, variant.onSurface)
+			meetsNormal := ratio >= normalTextContrastMinimum
+			meetsLarge := ratio >= largeTextContrastMinimum
+			if !meetsLarge || !meetsNormal {
+				issues = append(issues, ContrastIssue{
+					ID:              id,
+					Variant:         variant.name,
+					Ratio:           ratio,
+					MeetsLargeText:  meetsLarge,
+					MeetsNormalText: meetsNormal,
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// AutoAdjust nudges the lightness of any of this Colors' entries that fails Validate's normal-text threshold, in
+// OKLab space, toward whichever lightness extreme (0 or 1) it is already closer to, until the threshold is met or
+// autoAdjustMaxIterations is reached. Hue is preserved throughout. Unlike the package-level Current() theme, this
+// operates on the receiver's own data, so it can adjust a Colors loaded from a file without first making it current.
+// Colors.Save() writes the colors of the current theme, so the adjusted colors are saved only after MakeCurrent.
+func (c *Colors) AutoAdjust() {
+	for _, clr := range c.data {
+		clr.Light = autoAdjustVariant(clr.Light, func() unison.Color { return clr.DeriveOn().Light })
+		clr.Dark = autoAdjustVariant(clr.Dark, func() unison.Color { return clr.DeriveOn().Dark })
+	}
+}
+
+func autoAdjustVariant(surface unison.Color, onOf func() unison.Color) unison.Color {
+	l, a, b := rgbToOKLab(surface)
+	darker := l < 0.5
+	for i := 0; i < autoAdjustMaxIterations; i++ {
+		if contrastRatio(surface, onOf()) >= normalTextContrastMinimum {
+			break
+		}
+		if darker {
+			l = math.Max(0, l-autoAdjustLightnessStep)
+		} else {
+			l = math.Min(1, l+autoAdjustLightnessStep)
+		}
+		surface = oklabToRGB(l, a, b)
+	}
+	return surface
+}
+
+// contrastRatio returns the WCAG 2.1 contrast ratio between two colors, a value from 1 (no contrast) to 21 (black on
+// white).
+func contrastRatio(c1, c2 unison.Color) float64 {
+	l1 := relativeLuminance(c1)
+	l2 := relativeLuminance(c2)
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// relativeLuminance implements the WCAG 2.1 relative luminance formula.
+func relativeLuminance(clr unison.Color) float64 {
+	r := srgbChannelToLinear(float64(clr.Red()) / 255)
+	g := srgbChannelToLinear(float64(clr.Green()) / 255)
+	b := srgbChannelToLinear(float64(clr.Blue()) / 255)
+	return 0.2126*r + 0.7152*g + 0.0722*b
+}
+
+func srgbChannelToLinear(c float64) float64 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func linearToSRGBChannel(c float64) float64 {
+	c = math.Max(0, math.Min(1, c))
+	if c <= 0.0031308 {
+		return c * 12.92
+	}
+	return 1.055*math.Pow(c, 1/2.4) - 0.055
+}
+
+// rgbToOKLab converts a color to the OKLab color space (see Björn Ottosson, "A perceptual color space for image
+// processing"). L is lightness (0-1), a/b describe chromaticity (roughly green-red and blue-yellow).
+func rgbToOKLab(clr unison.Color) (l, a, b float64) {
+	r := srgbChannelToLinear(float64(clr.Red()) / 255)
+	g := srgbChannelToLinear(float64(clr.Green()) / 255)
+	bl := srgbChannelToLinear(float64(clr.Blue()) / 255)
+
+	lMS := 0.4122214708*r + 0.5363325363*g + 0.0514459929*bl
+	mMS := 0.2119034982*r + 0.6806995451*g + 0.1073969566*bl
+	sMS := 0.0883024619*r + 0.2817188376*g + 0.6299787005*bl
+
+	lMS = math.Cbrt(lMS)
+	mMS = math.Cbrt(mMS)
+	sMS = math.Cbrt(sMS)
+
+	l = 0.2104542553*lMS + 0.7936177850*mMS - 0.0040720468*sMS
+	a = 1.9779984951*lMS - 2.4285922050*mMS + 0.4505937099*sMS
+	b = 0.0259040371*lMS + 0.7827717662*mMS - 0.8086757660*sMS
+	return l, a, b
+}
+
+// oklabToRGB is the inverse of rgbToOKLab, clamping the result to valid 8-bit sRGB channels.
+func oklabToRGB(l, a, b float64) unison.Color {
+	lMS := l + 0.3963377774*a + 0.2158037573*b
+	mMS := l - 0.1055613458*a - 0.0638541728*b
+	sMS := l - 0.0894841775*a - 1.2914855480*b
+
+	lMS = lMS * lMS * lMS
+	mMS = mMS * mMS * mMS
+	sMS = sMS * sMS * sMS
+
+	r := +4.0767416621*lMS - 3.3077115913*mMS + 0.2309699292*sMS
+	g := -1.2684380046*lMS + 2.6097574011*mMS - 0.3413193965*sMS
+	bl := -0.0041960863*lMS - 0.7034186147*mMS + 1.7076147010*sMS
+
+	toByte := func(c float64) int {
+		return int(math.Round(linearToSRGBChannel(c) * 255))
+	}
+	return unison.RGB(toByte(r), toByte(g), toByte(bl))
+}
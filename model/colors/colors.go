@@ -48,11 +48,13 @@ type ThemedColor struct {
 
 // Colors holds a set of themed colors.
 type Colors struct {
-	data map[string]*unison.ThemeColor // Just here for serialization
+	data    map[string]*unison.ThemeColor // Just here for serialization
+	profile string                        // the selected profile; ProfileNormal uses the Light/Dark pair as-is
 }
 
 type fileData struct {
-	Version int `json:"version"`
+	Version int    `json:"version"`
+	Profile string `json:"profile,omitempty"`
 	Colors
 }
 
@@ -103,6 +105,7 @@ func NewFromFS(fileSystem fs.FS, filePath string) (*Colors, error) {
 	if data.Version > currentVersion {
 		return nil, errs.New("The theme color data is too new to be used")
 	}
+	data.Colors.profile = data.Profile
 	return &data.Colors, nil
 }
 
@@ -110,6 +113,7 @@ func NewFromFS(fileSystem fs.FS, filePath string) (*Colors, error) {
 func (c *Colors) Save(filePath string) error {
 	return jio.SaveToFile(context.Background(), filePath, &fileData{
 		Version: currentVersion,
+		Profile: c.profile,
 		Colors:  *c,
 	})
 }
@@ -151,12 +155,22 @@ func (c *Colors) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// MakeCurrent applies these colors to the current theme color set and updates all windows.
-func (c *Colors) MakeCurrent() {
+// MakeCurrent applies these colors to the current theme color set and updates all windows. If profile is not
+// ProfileNormal, each color's Light and Dark values are first transformed by ApplyProfile for that profile; otherwise
+// the colors are applied as-is. The selected profile is remembered and persisted the next time Save is called.
+func (c *Colors) MakeCurrent(profile string) {
+	c.profile = profile
 	for _, one := range Current() {
-		if v, ok := c.data[one.ID]; ok {
-			*one.Color = *v
+		v, ok := c.data[one.ID]
+		if !ok {
+			continue
+		}
+		clr := *v
+		if profile != ProfileNormal {
+			clr.Light = ApplyProfile(profile, clr.Light)
+			clr.Dark = ApplyProfile(profile, clr.Dark)
 		}
+		*one.Color = clr
 	}
 	unison.ThemeChanged()
 }
@@ -0,0 +1,234 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package colors
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/richardwilkes/json"
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/unison"
+)
+
+// ThemeImporter parses theme data in some external format and returns a mapping from GCS ThemedColor ID to the
+// unison.Color it should be set to.
+type ThemeImporter func(data []byte) (map[string]unison.Color, error)
+
+// ThemeExporter renders the given themed colors, keyed by ThemedColor ID, into some external format.
+type ThemeExporter func(data map[string]*unison.ThemeColor) ([]byte, error)
+
+type themeFormat struct {
+	importer ThemeImporter
+	exporter ThemeExporter
+}
+
+var themeFormats = make(map[string]themeFormat)
+
+// RegisterThemeFormat registers an importer and/or exporter for an external theme file format, keyed by a short
+// format name (e.g. "vscode", "base16"). Either may be nil if that direction isn't supported for the format.
+func RegisterThemeFormat(name string, importer ThemeImporter, exporter ThemeExporter) {
+	themeFormats[name] = themeFormat{importer: importer, exporter: exporter}
+}
+
+func init() {
+	RegisterThemeFormat("vscode", importVSCodeTheme, exportVSCodeTheme)
+	RegisterThemeFormat("base16", importBase16Theme, exportBase16Theme)
+}
+
+// DetectThemeFormat guesses the registered format name from a file's extension, returning "" if none match.
+func DetectThemeFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "vscode"
+	case ".yaml", ".yml":
+		return "base16"
+	default:
+		return ""
+	}
+}
+
+// ImportFrom loads colors from an external theme file and merges them into this Colors. format may be "" to
+// auto-detect from the file's extension. Only the colors the format maps onto a known ThemedColor ID are changed;
+// both the light and dark variant are set to the same imported value, since most external formats don't distinguish
+// between the two.
+func (c *Colors) ImportFrom(fileSystem fs.FS, path, format string) error {
+	if format == "" {
+		if format = DetectThemeFormat(path); format == "" {
+			return errs.New("unable to determine theme format for " + path)
+		}
+	}
+	f, ok := themeFormats[format]
+	if !ok || f.importer == nil {
+		return errs.New("no importer registered for theme format " + format)
+	}
+	data, err := fs.ReadFile(fileSystem, path)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	values, err := f.importer(data)
+	if err != nil {
+		return errs.NewWithCause("unable to parse "+format+" theme", err)
+	}
+	if c.data == nil {
+		c.data = make(map[string]*unison.ThemeColor, len(values))
+	}
+	for id, clr := range values {
+		if existing, ok2 := c.data[id]; ok2 {
+			existing.Light = clr
+			existing.Dark = clr
+		} else {
+			c.data[id] = &unison.ThemeColor{Light: clr, Dark: clr}
+		}
+	}
+	return nil
+}
+
+// ExportTo writes this Colors to an external theme file. format may be "" to auto-detect from the file's extension.
+func (c *Colors) ExportTo(path, format string) error {
+	if format == "" {
+		if format = DetectThemeFormat(path); format == "" {
+			return errs.New("unable to determine theme format for " + path)
+		}
+	}
+	f, ok := themeFormats[format]
+	if !ok || f.exporter == nil {
+		return errs.New("no exporter registered for theme format " + format)
+	}
+	data, err := f.exporter(c.data)
+	if err != nil {
+		return errs.NewWithCause("unable to render "+format+" theme", err)
+	}
+	return os.WriteFile(path, data, 0o644) //nolint:gosec // theme file, not sensitive
+}
+
+// vsCodeColorSlots maps the VSCode theme JSON "colors" keys we understand onto GCS ThemedColor IDs.
+var vsCodeColorSlots = map[string]string{
+	"editor.background":              "surface",
+	"titleBar.activeBackground":      "header",
+	"editor.lineHighlightBackground": "banding",
+	"focusBorder":                    "focus",
+	"editorHoverWidget.background":   "tooltip",
+	"editorError.foreground":         "error",
+	"editorWarning.foreground":       "warning",
+}
+
+func importVSCodeTheme(data []byte) (map[string]unison.Color, error) {
+	var theme struct {
+		Colors map[string]string `json:"colors"`
+	}
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return nil, err
+	}
+	result := make(map[string]unison.Color)
+	for slot, id := range vsCodeColorSlots {
+		if hex, ok := theme.Colors[slot]; ok {
+			clr, err := parseHexColor(hex)
+			if err != nil {
+				continue
+			}
+			result[id] = clr
+		}
+	}
+	return result, nil
+}
+
+// exportVSCodeTheme renders the colors we understand as a minimal VSCode color theme JSON file. Only the "colors"
+// slots vsCodeColorSlots knows about are emitted; everything else about a VSCode theme (token colors, UI theme kind,
+// etc.) is left for the user to fill in after importing the file into an editor.
+func exportVSCodeTheme(data map[string]*unison.ThemeColor) ([]byte, error) {
+	theme := struct {
+		Colors map[string]string `json:"colors"`
+	}{Colors: make(map[string]string, len(vsCodeColorSlots))}
+	for slot, id := range vsCodeColorSlots {
+		if clr, ok := data[id]; ok {
+			theme.Colors[slot] = colorToHex(clr.Light)
+		}
+	}
+	return json.Marshal(&theme)
+}
+
+// base16Slots maps the base16 scheme "baseXX" keys we understand onto GCS ThemedColor IDs.
+var base16Slots = map[string]string{
+	"base00": "surface",
+	"base01": "banding",
+	"base02": "header",
+	"base0C": "tooltip",
+	"base0D": "focus",
+	"base08": "error",
+	"base0A": "warning",
+}
+
+// importBase16Theme parses the flat "key: value" structure of a base16 scheme file. A full YAML parser isn't needed
+// since base16 scheme files never nest or use flow style for the fields we care about.
+func importBase16Theme(data []byte) (map[string]unison.Color, error) {
+	result := make(map[string]unison.Color)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		id, known := base16Slots[key]
+		if !known {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		clr, err := parseHexColor(value)
+		if err != nil {
+			continue
+		}
+		result[id] = clr
+	}
+	return result, nil
+}
+
+// exportBase16Theme renders the colors we understand as a base16 scheme file's flat "key: value" lines. Only the
+// baseXX slots base16Slots knows about are emitted.
+func exportBase16Theme(data map[string]*unison.ThemeColor) ([]byte, error) {
+	var buffer strings.Builder
+	for _, key := range []string{"base00", "base01", "base02", "base08", "base0A", "base0C", "base0D"} {
+		id, known := base16Slots[key]
+		if !known {
+			continue
+		}
+		if clr, ok := data[id]; ok {
+			fmt.Fprintf(&buffer, "%s: %q\n", key, colorToHex(clr.Light))
+		}
+	}
+	return []byte(buffer.String()), nil
+}
+
+func colorToHex(clr unison.Color) string {
+	return fmt.Sprintf("#%02X%02X%02X", clr.Red(), clr.Green(), clr.Blue())
+}
+
+func parseHexColor(s string) (unison.Color, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) == 8 { // Drop an alpha channel if present (RRGGBBAA).
+		s = s[:6]
+	}
+	if len(s) != 6 {
+		return 0, errs.New("invalid hex color: " + s)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, errs.Wrap(err)
+	}
+	return unison.RGB(int(v>>16&0xFF), int(v>>8&0xFF), int(v&0xFF)), nil
+}
@@ -12,6 +12,7 @@ package gurps
 import (
 	"context"
 	"io/fs"
+	"path"
 	"strings"
 
 	"github.com/richardwilkes/gcs/v5/model/fxp"
@@ -31,8 +32,11 @@ const (
 // Ancestry holds details necessary to generate ancestry-specific customizations.
 type Ancestry struct {
 	Name          string                     `json:"name,omitempty"`
+	Inherits      []string                   `json:"inherits,omitempty"`
 	CommonOptions *AncestryOptions           `json:"common_options,omitempty"`
 	GenderOptions []*WeightedAncestryOptions `json:"gender_options,omitempty"`
+	fileSystem    fs.FS
+	dirPath       string
 }
 
 type ancestryData struct {
@@ -48,6 +52,17 @@ func AvailableAncestries(libraries Libraries) []*NamedFileSet {
 
 // LookupAncestry an Ancestry by name.
 func LookupAncestry(name string, libraries Libraries) *Ancestry {
+	a := lookupRawAncestry(name, libraries)
+	if a == nil {
+		return nil
+	}
+	return a.Resolve(libraries)
+}
+
+// lookupRawAncestry finds an Ancestry by name and returns it unresolved, i.e. without merging in anything it
+// inherits. Used by Ancestry.resolve so that inheritance cycle detection's "seen" map carries through each hop of
+// the chain, instead of being reset by a fresh call to the public LookupAncestry/Resolve entry points.
+func lookupRawAncestry(name string, libraries Libraries) *Ancestry {
 	for _, lib := range AvailableAncestries(libraries) {
 		for _, one := range lib.List {
 			if one.Name == name {
@@ -81,6 +96,8 @@ func NewAncestryFromFile(fileSystem fs.FS, filePath string) (*Ancestry, error) {
 	if ancestry.Name == "" {
 		ancestry.Name = xfs.BaseName(filePath)
 	}
+	ancestry.Ancestry.fileSystem = fileSystem
+	ancestry.Ancestry.dirPath = path.Dir(filePath)
 	return &ancestry.Ancestry, nil
 }
 
@@ -191,13 +208,80 @@ func (a *Ancestry) RandomHandedness(gender, not string) string {
 	return defaultHandedness
 }
 
-// RandomName returns a randomized name.
-func (a *Ancestry) RandomName(nameGeneratorRefs []*NameGeneratorRef, gender string) string {
+// NameGeneratorRef refers to a named generator that can produce a random name. The generator itself is looked up by
+// Name within the set of generators shipped with (or alongside) an ancestry.
+type NameGeneratorRef struct {
+	Name   string `json:"name,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Weight int    `json:"weight,omitempty"`
+
+	// CorpusPath is the path, relative to the ancestry file, of a plain-text corpus of example names, one per line.
+	// Only used when Type is MarkovNameGenerator.
+	CorpusPath string `json:"corpus_path,omitempty"`
+	// Order is the number of preceding characters used to predict the next one. Only used when Type is
+	// MarkovNameGenerator. Defaults to 3 when zero.
+	Order int `json:"order,omitempty"`
+	// MinLength and MaxLength bound the length of a generated name. Only used when Type is MarkovNameGenerator.
+	MinLength int `json:"min_length,omitempty"`
+	MaxLength int `json:"max_length,omitempty"`
+}
+
+func (r *NameGeneratorRef) kind() string {
+	if r.Type == "" {
+		return WeightedNameGenerator
+	}
+	return r.Type
+}
+
+// RandomName returns a randomized name, retrying like its sibling Random* methods if the result equals not.
+func (a *Ancestry) RandomName(nameGeneratorRefs []*NameGeneratorRef, gender, not string) string {
 	if options := a.GenderedOptions(gender); options != nil && len(options.NameGenerators) != 0 {
-		return options.RandomName(nameGeneratorRefs)
+		if name := a.randomMarkovName(nameGeneratorRefs, not); name != "" {
+			return name
+		}
+		return options.RandomName(nameGeneratorRefs, not)
 	}
 	if a.CommonOptions != nil && len(a.CommonOptions.NameGenerators) != 0 {
-		return a.CommonOptions.RandomName(nameGeneratorRefs)
+		if name := a.randomMarkovName(nameGeneratorRefs, not); name != "" {
+			return name
+		}
+		return a.CommonOptions.RandomName(nameGeneratorRefs, not)
+	}
+	return ""
+}
+
+// randomMarkovName looks for a MarkovNameGenerator amongst the given refs and, if found, generates a name from its
+// trained model, avoiding not if possible. Returns "" if none of the refs use the markov generator, so the caller can
+// fall back to the original weighted-list behavior.
+func (a *Ancestry) randomMarkovName(nameGeneratorRefs []*NameGeneratorRef, not string) string {
+	if a.fileSystem == nil {
+		return ""
+	}
+	for _, ref := range nameGeneratorRefs {
+		if ref.kind() != MarkovNameGenerator || ref.CorpusPath == "" {
+			continue
+		}
+		order := ref.Order
+		if order == 0 {
+			order = defaultMarkovOrder
+		}
+		minLength := ref.MinLength
+		if minLength == 0 {
+			minLength = defaultMarkovMinLength
+		}
+		maxLength := ref.MaxLength
+		if maxLength == 0 {
+			maxLength = defaultMarkovMaxLength
+		}
+		model, err := loadOrTrainMarkovModel(a.fileSystem, path.Join(a.dirPath, ref.CorpusPath), order, minLength,
+			maxLength)
+		if err != nil {
+			errs.Log(err, "corpus", ref.CorpusPath)
+			continue
+		}
+		if name := model.generate(globalMarkovRand(), not); name != "" {
+			return name
+		}
 	}
 	return ""
 }
@@ -217,6 +301,25 @@ func ActiveAncestries(list []*Trait) []*Ancestry {
 	return ancestries
 }
 
+// CombinedActiveAncestry merges the options of every Ancestry enabled in the given Trait nodes and their descendants
+// into a single synthetic Ancestry, so a mixed-heritage character rolls from the union of all of its ancestries
+// instead of just the first one found. Returns nil if no ancestry is active.
+func CombinedActiveAncestry(list []*Trait) *Ancestry {
+	ancestries := ActiveAncestries(list)
+	if len(ancestries) == 0 {
+		return nil
+	}
+	combined := ancestries[0]
+	for _, one := range ancestries[1:] {
+		combined = &Ancestry{
+			Name:          combined.Name,
+			CommonOptions: mergeAncestryOptions(combined.CommonOptions, one.CommonOptions),
+			GenderOptions: mergeGenderOptions(combined.GenderOptions, one.GenderOptions),
+		}
+	}
+	return combined
+}
+
 // ActiveAncestryTraits returns the Traits that have Ancestry data and are enabled within the given traits or their
 // descendants.
 func ActiveAncestryTraits(list []*Trait) []*Trait {
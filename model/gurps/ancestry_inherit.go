@@ -0,0 +1,134 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package gurps
+
+import "github.com/richardwilkes/toolbox/errs"
+
+// Resolve returns the fully-flattened version of this Ancestry, with any ancestries named in Inherits merged in.
+// Values set directly on this Ancestry take precedence over anything inherited, and weighted option lists (such as
+// GenderOptions) are combined by summing the weights of identical entries rather than duplicating them. An
+// inheritance cycle is logged and otherwise ignored, returning whatever could be resolved before the cycle was
+// detected.
+func (a *Ancestry) Resolve(libraries Libraries) *Ancestry {
+	return a.resolve(libraries, make(map[string]bool))
+}
+
+func (a *Ancestry) resolve(libraries Libraries, seen map[string]bool) *Ancestry {
+	if len(a.Inherits) == 0 {
+		return a
+	}
+	if seen[a.Name] {
+		errs.Log(errs.New("ancestry inheritance cycle detected"), "name", a.Name)
+		return a
+	}
+	// Mark a.Name as seen only for the duration of this branch of the DFS, then remove it again before returning, so
+	// a diamond (two parents sharing a common non-leaf grandparent) isn't mistaken for a cycle the second time that
+	// grandparent is reached through a different branch.
+	seen[a.Name] = true
+	defer delete(seen, a.Name)
+	resolved := &Ancestry{
+		Name:          a.Name,
+		CommonOptions: a.CommonOptions,
+		GenderOptions: a.GenderOptions,
+		fileSystem:    a.fileSystem,
+		dirPath:       a.dirPath,
+	}
+	for _, parentName := range a.Inherits {
+		parent := lookupRawAncestry(parentName, libraries)
+		if parent == nil {
+			errs.Log(errs.New("unable to resolve inherited ancestry"), "name", parentName, "child", a.Name)
+			continue
+		}
+		parent = parent.resolve(libraries, seen)
+		resolved.CommonOptions = mergeAncestryOptions(parent.CommonOptions, resolved.CommonOptions)
+		resolved.GenderOptions = mergeGenderOptions(parent.GenderOptions, resolved.GenderOptions)
+	}
+	return resolved
+}
+
+// mergeAncestryOptions returns a copy of child with any fields it leaves unset filled in from parent, so a "Half-Elf"
+// file need only specify the formulas and tables that differ from its parents.
+func mergeAncestryOptions(parent, child *AncestryOptions) *AncestryOptions {
+	switch {
+	case parent == nil:
+		return child
+	case child == nil:
+		return parent
+	}
+	merged := *child
+	if merged.HeightFormula == "" {
+		merged.HeightFormula = parent.HeightFormula
+	}
+	if merged.WeightFormula == "" {
+		merged.WeightFormula = parent.WeightFormula
+	}
+	if merged.AgeFormula == "" {
+		merged.AgeFormula = parent.AgeFormula
+	}
+	merged.HairOptions = mergeWeightedStrings(parent.HairOptions, child.HairOptions)
+	merged.EyeOptions = mergeWeightedStrings(parent.EyeOptions, child.EyeOptions)
+	merged.SkinOptions = mergeWeightedStrings(parent.SkinOptions, child.SkinOptions)
+	merged.HandednessOptions = mergeWeightedStrings(parent.HandednessOptions, child.HandednessOptions)
+	merged.NameGenerators = append(append([]*NameGeneratorRef{}, parent.NameGenerators...), child.NameGenerators...)
+	return &merged
+}
+
+// mergeGenderOptions combines two sets of gendered options, summing the weight of any entries that share the same
+// gender name rather than producing duplicate entries for it.
+func mergeGenderOptions(parent, child []*WeightedAncestryOptions) []*WeightedAncestryOptions {
+	if len(parent) == 0 {
+		return child
+	}
+	if len(child) == 0 {
+		return parent
+	}
+	merged := make([]*WeightedAncestryOptions, 0, len(parent)+len(child))
+	byName := make(map[string]*WeightedAncestryOptions, len(parent))
+	for _, one := range parent {
+		clone := &WeightedAncestryOptions{Weight: one.Weight, Value: one.Value}
+		merged = append(merged, clone)
+		byName[clone.Value.Name] = clone
+	}
+	for _, one := range child {
+		if existing, ok := byName[one.Value.Name]; ok {
+			existing.Weight += one.Weight
+			existing.Value = mergeAncestryOptions(existing.Value, one.Value)
+		} else {
+			merged = append(merged, one)
+		}
+	}
+	return merged
+}
+
+// mergeWeightedStrings combines two weighted string option lists, summing the weight of identical values instead of
+// listing them twice.
+func mergeWeightedStrings(parent, child []*WeightedString) []*WeightedString {
+	if len(parent) == 0 {
+		return child
+	}
+	if len(child) == 0 {
+		return parent
+	}
+	merged := make([]*WeightedString, 0, len(parent)+len(child))
+	byValue := make(map[string]*WeightedString, len(parent))
+	for _, one := range parent {
+		clone := &WeightedString{Weight: one.Weight, Value: one.Value}
+		merged = append(merged, clone)
+		byValue[clone.Value] = clone
+	}
+	for _, one := range child {
+		if existing, ok := byValue[one.Value]; ok {
+			existing.Weight += one.Weight
+		} else {
+			merged = append(merged, one)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+// Package tmcost holds the types of cost a TraitModifier may use.
+package tmcost
+
+import "strings"
+
+// Type holds the type of cost adjustment a TraitModifier applies.
+type Type byte
+
+// Possible values for Type. Percentage, Points, and Multiplier are the original set; Expression was added later to
+// let a modifier's cost be computed from a formula instead of a fixed number, and must stay appended after them so
+// the ordinal values of the original three don't change. JSON stores a Type by its key, not its ordinal value.
+const (
+	Percentage Type = iota
+	Points
+	Multiplier
+	Expression
+)
+
+var typeData = []struct {
+	Key    string
+	String string
+}{
+	{Key: "percentage", String: "%"},
+	{Key: "points", String: ""},
+	{Key: "multiplier", String: "x"},
+	{Key: "expression", String: ""},
+}
+
+// EnsureValid returns the first Type if this Type is not a known value.
+func (t Type) EnsureValid() Type {
+	if int(t) < len(typeData) {
+		return t
+	}
+	return 0
+}
+
+// Key returns the key used to represent this Type in JSON.
+func (t Type) Key() string {
+	return typeData[t.EnsureValid()].Key
+}
+
+// String implements fmt.Stringer.
+func (t Type) String() string {
+	return typeData[t.EnsureValid()].String
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t Type) MarshalText() ([]byte, error) {
+	return []byte(t.Key()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. An unrecognized key is treated as Percentage, the original
+// default before other cost types existed.
+func (t *Type) UnmarshalText(text []byte) error {
+	key := strings.ToLower(string(text))
+	for i, one := range typeData {
+		if one.Key == key {
+			*t = Type(i)
+			return nil
+		}
+	}
+	*t = Percentage
+	return nil
+}
@@ -0,0 +1,20 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package gurps
+
+// Name generator kinds. "weighted" is the original scheme, where RandomName simply chooses amongst a fixed, hand
+// authored list of full names. "markov" trains a character-level Markov model from a corpus of example names and
+// generates novel ones that still "sound like" the corpus.
+const (
+	WeightedNameGenerator = "weighted"
+	MarkovNameGenerator   = "markov"
+)
+
+// NameGeneratorRef is declared in ancestry.go, alongside Ancestry.RandomName.
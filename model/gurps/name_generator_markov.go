@@ -0,0 +1,212 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package gurps
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"math/rand"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/richardwilkes/json"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+//nolint:gosec // name generation has no security implications
+var markovRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+func globalMarkovRand() *rand.Rand {
+	return markovRand
+}
+
+const (
+	defaultMarkovOrder     = 3
+	defaultMarkovMinLength = 3
+	defaultMarkovMaxLength = 20
+	markovMaxRetries       = 50
+	markovStartSentinel    = '\x01'
+	markovEndSentinel      = '\x02'
+	markovCacheExt         = ".markovcache"
+)
+
+// markovModel is an order-N character Markov model trained from a corpus of example names. It is used to generate
+// novel names for an ancestry without hand-authoring a large weighted list.
+type markovModel struct {
+	Order       int                       `json:"order"`
+	MinLength   int                       `json:"min_length"`
+	MaxLength   int                       `json:"max_length"`
+	CorpusHash  string                    `json:"corpus_hash"`
+	Transitions map[string]map[string]int `json:"transitions"`
+}
+
+// trainMarkovModel builds a model from a list of example names.
+func trainMarkovModel(names []string, order, minLength, maxLength int, corpusHash string) *markovModel {
+	if order < 1 {
+		order = defaultMarkovOrder
+	}
+	if minLength < 1 {
+		minLength = defaultMarkovMinLength
+	}
+	if maxLength < minLength {
+		maxLength = defaultMarkovMaxLength
+	}
+	m := &markovModel{
+		Order:       order,
+		MinLength:   minLength,
+		MaxLength:   maxLength,
+		CorpusHash:  corpusHash,
+		Transitions: make(map[string]map[string]int),
+	}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		window := make([]rune, order)
+		for i := range window {
+			window[i] = markovStartSentinel
+		}
+		for _, r := range append([]rune(name), markovEndSentinel) {
+			key := string(window)
+			freq := m.Transitions[key]
+			if freq == nil {
+				freq = make(map[string]int)
+				m.Transitions[key] = freq
+			}
+			freq[string(r)]++
+			window = append(window[1:], r)
+		}
+	}
+	return m
+}
+
+// generate produces a single name, retrying up to markovMaxRetries times if the result collides with not.
+func (m *markovModel) generate(rng *rand.Rand, not string) string {
+	for attempt := 0; attempt < markovMaxRetries; attempt++ {
+		if name := m.generateOnce(rng); name != "" && !strings.EqualFold(name, not) {
+			return name
+		}
+	}
+	return m.generateOnce(rng)
+}
+
+func (m *markovModel) generateOnce(rng *rand.Rand) string {
+	var buffer []rune
+	window := make([]rune, m.Order)
+	for i := range window {
+		window[i] = markovStartSentinel
+	}
+	for len(buffer) < m.MaxLength {
+		freq, ok := m.Transitions[string(window)]
+		if !ok || len(freq) == 0 {
+			break
+		}
+		next := weightedSampleRune(rng, freq)
+		if next == markovEndSentinel {
+			if len(buffer) >= m.MinLength || len(freq) == 1 {
+				break
+			}
+			// Too short yet; ignore the end marker and try again for this window, unless the end marker is the only
+			// continuation it has (checked above), in which case the short name is returned as-is.
+			continue
+		}
+		buffer = append(buffer, next)
+		window = append(window[1:], next)
+	}
+	return string(buffer)
+}
+
+func weightedSampleRune(rng *rand.Rand, freq map[string]int) rune {
+	total := 0
+	for _, count := range freq {
+		total += count
+	}
+	if total <= 0 {
+		return markovEndSentinel
+	}
+	pick := rng.Intn(total)
+	for r, count := range freq {
+		if pick < count {
+			return []rune(r)[0]
+		}
+		pick -= count
+	}
+	return markovEndSentinel
+}
+
+// loadOrTrainMarkovModel loads a trained model from the cache file next to the corpus if it is still valid for the
+// corpus' current contents, otherwise trains a fresh model and writes it back to the cache (when the filesystem is
+// writable).
+func loadOrTrainMarkovModel(fileSystem fs.FS, corpusPath string, order, minLength, maxLength int) (*markovModel, error) {
+	data, err := fs.ReadFile(fileSystem, corpusPath)
+	if err != nil {
+		return nil, errs.NewWithCause("unable to read name generator corpus", err)
+	}
+	hash := sha256.Sum256(data)
+	corpusHash := hex.EncodeToString(hash[:])
+	cachePath := corpusPath + markovCacheExt
+	if cached, err := loadMarkovCache(fileSystem, cachePath); err == nil && cached.CorpusHash == corpusHash &&
+		cached.Order == order {
+		return cached, nil
+	}
+	var names []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			names = append(names, line)
+		}
+	}
+	model := trainMarkovModel(names, order, minLength, maxLength, corpusHash)
+	saveMarkovCache(fileSystem, cachePath, model)
+	return model, nil
+}
+
+func loadMarkovCache(fileSystem fs.FS, cachePath string) (*markovModel, error) {
+	data, err := fs.ReadFile(fileSystem, cachePath)
+	if err != nil {
+		return nil, err
+	}
+	var model markovModel
+	if err = json.Unmarshal(data, &model); err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// cwdFS is a filesystem rooted at the process's current working directory, used by saveMarkovCache to recognize one.
+// Compared with reflect.DeepEqual rather than direct equality, since the underlying type os.DirFS returns isn't
+// guaranteed to be comparable.
+var cwdFS = os.DirFS(".")
+
+// saveMarkovCache persists the trained model next to the corpus so startup stays fast. cachePath is resolved relative
+// to fileSystem, but os.WriteFile always resolves relative to the process's current working directory; those only
+// agree when fileSystem is itself rooted at the cwd. For any other fileSystem (the embedded, read-only data set, or a
+// library fs.FS rooted elsewhere on disk), there's no portable way to recover the real OS path from an fs.FS, so
+// writing is skipped rather than risk silently writing to (or clobbering) the wrong file. Retraining at startup is
+// merely slower, not incorrect, so skipping is safe.
+func saveMarkovCache(fileSystem fs.FS, cachePath string, model *markovModel) {
+	if !reflect.DeepEqual(fileSystem, cwdFS) {
+		return
+	}
+	data, err := json.Marshal(model)
+	if err != nil {
+		errs.Log(err, "path", cachePath)
+		return
+	}
+	if err = os.WriteFile(cachePath, data, 0o644); err != nil { //nolint:gosec // cache file, not sensitive
+		errs.Log(err, "path", cachePath)
+	}
+}
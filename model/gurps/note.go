@@ -223,8 +223,11 @@ func (n *Note) String() string {
 	return n.resolveText()
 }
 
+// resolveText evaluates any embedded expressions and turns any "[[Note Title]]" or "[[trait:Name]]" style
+// cross-references into clickable markdown links. A reference that can't be resolved is left as literal text.
 func (n *Note) resolveText() string {
-	return EvalEmbeddedRegex.ReplaceAllStringFunc(n.Text, n.Entity.EmbeddedEval)
+	text := EvalEmbeddedRegex.ReplaceAllStringFunc(n.Text, n.Entity.EmbeddedEval)
+	return n.resolveLinks(text)
 }
 
 // NotesHeaderData returns the header data information for the given note column.
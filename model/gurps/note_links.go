@@ -0,0 +1,127 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package gurps
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/richardwilkes/toolbox/tid"
+)
+
+// noteLinkRegex matches "[[Note Title]]" and "[[kind:Name]]" style cross-references within a Note's Text.
+var noteLinkRegex = regexp.MustCompile(`\[\[([^\[\]]+)\]\]`)
+
+// NoteLink describes a single cross-reference found within a Note's text.
+type NoteLink struct {
+	// Raw is the exact text that appeared between the double brackets.
+	Raw string
+	// Kind is the prefix before the colon, e.g. "trait", or "" for a plain note title reference.
+	Kind string
+	// Target is the title or name being referenced, with any "kind:" prefix stripped.
+	Target string
+}
+
+// Links returns every cross-reference found in this Note's text, in the order they appear.
+func (n *Note) Links() []NoteLink {
+	matches := noteLinkRegex.FindAllStringSubmatch(n.Text, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	links := make([]NoteLink, 0, len(matches))
+	for _, match := range matches {
+		links = append(links, parseNoteLink(match[1]))
+	}
+	return links
+}
+
+func parseNoteLink(raw string) NoteLink {
+	if kind, target, ok := strings.Cut(raw, ":"); ok {
+		return NoteLink{Raw: raw, Kind: strings.TrimSpace(kind), Target: strings.TrimSpace(target)}
+	}
+	return NoteLink{Raw: raw, Target: strings.TrimSpace(raw)}
+}
+
+// resolveLinks replaces any "[[...]]" cross-reference with a markdown link once its target can be resolved.
+// References that can't be resolved (e.g. a typo, or a note that was since deleted) are left as literal text so
+// older content round-trips without producing broken links.
+func (n *Note) resolveLinks(text string) string {
+	if n.Entity == nil {
+		return text
+	}
+	titles := buildNoteTitleIndex(n.Entity)
+	traits := buildTraitNameIndex(n.Entity)
+	return noteLinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+		link := parseNoteLink(match[2 : len(match)-2])
+		switch link.Kind {
+		case "":
+			if id, ok := titles[strings.ToLower(link.Target)]; ok {
+				return "[" + link.Target + "](gcs-link:note:" + string(id) + ")"
+			}
+		case "trait":
+			if id, ok := traits[strings.ToLower(link.Target)]; ok {
+				return "[" + link.Target + "](gcs-link:trait:" + string(id) + ")"
+			}
+		}
+		return match
+	})
+}
+
+// noteTitle returns the title used to match "[[Title]]" references against a Note: its text, up to the first
+// newline, trimmed and lower-cased for case-insensitive comparison.
+func noteTitle(text string) string {
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(text))
+}
+
+// buildNoteTitleIndex walks every note owned by the Entity, including children of note containers, and returns a map
+// from note title to TID so links can be resolved.
+func buildNoteTitleIndex(entity *Entity) map[string]tid.TID {
+	index := make(map[string]tid.TID)
+	Traverse(func(n *Note) bool {
+		index[noteTitle(n.Text)] = n.TID
+		return false
+	}, true, true, entity.Notes...)
+	return index
+}
+
+// buildTraitNameIndex walks every trait owned by the Entity, including children of container traits, and returns a
+// map from trait name to TID so "[[trait:Name]]" links can be resolved.
+func buildTraitNameIndex(entity *Entity) map[string]tid.TID {
+	index := make(map[string]tid.TID)
+	Traverse(func(t *Trait) bool {
+		index[strings.ToLower(t.Name)] = t.LocalID
+		return false
+	}, true, true, entity.Traits...)
+	return index
+}
+
+// NoteBacklinks returns every Note owned by the Entity (including children of note containers) whose text contains a
+// plain "[[Title]]" link that resolves to the note identified by target. Used by the UI to show a "referenced by"
+// pane alongside a note.
+func (e *Entity) NoteBacklinks(target tid.TID) []*Note {
+	titles := buildNoteTitleIndex(e)
+	var backlinks []*Note
+	Traverse(func(n *Note) bool {
+		for _, link := range n.Links() {
+			if link.Kind != "" {
+				continue
+			}
+			if id, ok := titles[strings.ToLower(link.Target)]; ok && id == target {
+				backlinks = append(backlinks, n)
+				break
+			}
+		}
+		return false
+	}, true, true, e.Notes...)
+	return backlinks
+}
@@ -58,6 +58,15 @@ type Trait struct {
 	TraitData
 	Entity            *Entity
 	UnsatisfiedReason string
+
+	// cachedModifiers and cachedPoints memoize AllModifiers() and AdjustedPoints(), since both recurse over the
+	// whole trait tree and are recomputed on every sheet refresh. They're invalidated by invalidateCache() (called
+	// from SetOwningEntity) and invalidateCacheDownward() (called from CopyFrom, ApplyTo, UnmarshalJSON, SetDisabled,
+	// and when one of the trait's TraitModifiers is enabled, disabled, or copied into).
+	cachedModifiers     []*TraitModifier
+	modifiersCacheValid bool
+	cachedPoints        fxp.Int
+	pointsCacheValid    bool
 }
 
 // TraitData holds the Trait data that is written to disk.
@@ -102,6 +111,10 @@ type TraitContainerOnlyEditData struct {
 	Ancestry       string          `json:"ancestry,omitempty"`
 	TemplatePicker *TemplatePicker `json:"template_picker,omitempty"`
 	ContainerType  container.Type  `json:"container_type,omitempty"`
+	// TraitAdaptationList declares how to resolve Features, Weapons, or TraitModifiers that this container's
+	// children duplicate or conflict over. Only consulted for ContainerType container.MetaTrait and
+	// container.AlternativeAbilities. Absent in older files, which have no adaptation rules.
+	TraitAdaptationList []*TraitAdaptationRule `json:"trait_adaptations,omitempty"`
 }
 
 type traitListData struct {
@@ -250,6 +263,7 @@ func (t *Trait) UnmarshalJSON(data []byte) error {
 			one.parent = t
 		}
 	}
+	t.invalidateCacheDownward()
 	return nil
 }
 
@@ -372,7 +386,9 @@ func (t *Trait) SetOwningEntity(entity *Entity) {
 	}
 	for _, m := range t.Modifiers {
 		m.SetOwningEntity(entity)
+		m.setOwnerTrait(t)
 	}
+	t.invalidateCache()
 }
 
 // Notes returns the local notes.
@@ -393,8 +409,19 @@ func (t *Trait) CurrentLevel() fxp.Int {
 	return 0
 }
 
-// AdjustedPoints returns the total points, taking levels and modifiers into account.
+// AdjustedPoints returns the total points, taking levels and modifiers into account. The result is cached until
+// invalidateCache() or invalidateCacheDownward() runs.
 func (t *Trait) AdjustedPoints() fxp.Int {
+	if t.pointsCacheValid {
+		return t.cachedPoints
+	}
+	points := t.computeAdjustedPoints()
+	t.cachedPoints = points
+	t.pointsCacheValid = true
+	return points
+}
+
+func (t *Trait) computeAdjustedPoints() fxp.Int {
 	if t.EffectivelyDisabled() {
 		return 0
 	}
@@ -424,11 +451,17 @@ func (t *Trait) AdjustedPoints() fxp.Int {
 			points += one.AdjustedPoints()
 		}
 	}
+	points += t.adaptedPointsAdjustment()
 	return points
 }
 
-// AllModifiers returns the modifiers plus any inherited from parents.
+// AllModifiers returns the modifiers plus any inherited from parents. For a MetaTrait or AlternativeAbilities
+// container, this also includes the modifiers its children contribute, resolved through TraitAdaptationList. The
+// result is cached until invalidateCache() or invalidateCacheDownward() runs.
 func (t *Trait) AllModifiers() []*TraitModifier {
+	if t.modifiersCacheValid {
+		return t.cachedModifiers
+	}
 	all := make([]*TraitModifier, len(t.Modifiers))
 	copy(all, t.Modifiers)
 	p := t.parent
@@ -436,6 +469,9 @@ func (t *Trait) AllModifiers() []*TraitModifier {
 		all = append(all, p.Modifiers...)
 		p = p.parent
 	}
+	all = append(all, t.adaptedChildModifiers()...)
+	t.cachedModifiers = all
+	t.modifiersCacheValid = true
 	return all
 }
 
@@ -474,8 +510,12 @@ func (t *Trait) resolveLocalNotes() string {
 	return EvalEmbeddedRegex.ReplaceAllStringFunc(t.LocalNotes, t.Entity.EmbeddedEval)
 }
 
-// FeatureList returns the list of Features.
+// FeatureList returns the list of Features. For a MetaTrait or AlternativeAbilities container, this also aggregates
+// the Features its children contribute, resolved through TraitAdaptationList.
 func (t *Trait) FeatureList() Features {
+	if t.adaptsChildren() {
+		return append(slices.Clone(t.Features), t.adaptedChildFeatures()...)
+	}
 	return t.Features
 }
 
@@ -714,6 +754,7 @@ func (t *Trait) ClearUnusedFieldsForType() {
 		t.ContainerType = 0
 		t.TemplatePicker = nil
 		t.Ancestry = ""
+		t.TraitAdaptationList = nil
 		if !t.CanLevel {
 			t.Levels = 0
 			t.PointsPerLevel = 0
@@ -760,4 +801,7 @@ func (t *Trait) copyFrom(entity *Entity, other *Trait, isApply bool) {
 		}
 	}
 	t.TemplatePicker = t.TemplatePicker.Clone()
+	// Use the downward variant: this may have just replaced Disabled (or the whole Children tree via TraitData),
+	// so children's caches need invalidating too, not just ancestors'.
+	t.invalidateCacheDownward()
 }
@@ -0,0 +1,186 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package gurps
+
+import (
+	"fmt"
+
+	"github.com/richardwilkes/gcs/v5/model/fxp"
+	"github.com/richardwilkes/gcs/v5/model/gurps/enums/container"
+	"github.com/richardwilkes/gcs/v5/model/gurps/enums/tmcost"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// TraitAdaptationAction describes how a TraitAdaptationRule resolves a conflict or duplication amongst the Features,
+// Weapons, or TraitModifiers that the children of a MetaTrait or AlternativeAbilities container contribute.
+type TraitAdaptationAction string
+
+// Possible values for TraitAdaptationAction.
+const (
+	TraitAdaptationExclude     TraitAdaptationAction = "exclude"
+	TraitAdaptationAlias       TraitAdaptationAction = "alias"
+	TraitAdaptationKeepLargest TraitAdaptationAction = "keep_largest"
+)
+
+// TraitAdaptationRule declares how one conflict or duplication amongst a container's children should be resolved.
+type TraitAdaptationRule struct {
+	Action TraitAdaptationAction `json:"action"`
+	// Name identifies the TraitModifier or Feature the rule applies to. For TraitAdaptationExclude, empty means every
+	// name; the other actions only match a rule whose Name equals the contribution's name exactly.
+	Name string `json:"name,omitempty"`
+	// From names the child Trait this rule takes effect for. For TraitAdaptationExclude, empty means every child;
+	// TraitAdaptationAlias matches From exactly, and TraitAdaptationKeepLargest ignores From.
+	From string `json:"from,omitempty"`
+	// To names the child Trait an aliased contribution should be attributed to instead of From. Only meaningful for
+	// TraitAdaptationAlias.
+	To string `json:"to,omitempty"`
+}
+
+// adaptsChildren returns true if this is a container whose children's contributions should be resolved through
+// TraitAdaptationList rather than simply concatenated or summed.
+func (t *Trait) adaptsChildren() bool {
+	return t.Container() && (t.ContainerType == container.MetaTrait || t.ContainerType == container.AlternativeAbilities)
+}
+
+func (t *Trait) excludesByName(from, name string) bool {
+	for _, r := range t.TraitAdaptationList {
+		if r.Action == TraitAdaptationExclude && (r.From == "" || r.From == from) && (r.Name == "" || r.Name == name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Trait) keepsLargestByName(name string) bool {
+	for _, r := range t.TraitAdaptationList {
+		if r.Action == TraitAdaptationKeepLargest && r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// aliasedFrom returns the child name a contribution named 'name' coming from 'childName' should be attributed to,
+// after applying any matching TraitAdaptationAlias rule.
+func (t *Trait) aliasedFrom(childName, name string) string {
+	for _, r := range t.TraitAdaptationList {
+		if r.Action == TraitAdaptationAlias && r.Name == name && r.From == childName {
+			return r.To
+		}
+	}
+	return childName
+}
+
+// adaptedChildModifiers returns the TraitModifiers this container's children contribute, after TraitAdaptationList
+// has excluded, aliased, or deduplicated conflicting entries. Returns nil unless adaptsChildren() is true.
+func (t *Trait) adaptedChildModifiers() []*TraitModifier {
+	if !t.adaptsChildren() {
+		return nil
+	}
+	largest := make(map[string]*TraitModifier)
+	var kept []*TraitModifier
+	for _, child := range t.Children {
+		for _, m := range child.Modifiers {
+			from := t.aliasedFrom(child.Name, m.Name)
+			if t.excludesByName(from, m.Name) {
+				continue
+			}
+			if t.keepsLargestByName(m.Name) {
+				if existing, ok := largest[m.Name]; !ok || m.CostModifier() > existing.CostModifier() {
+					largest[m.Name] = m
+				}
+				continue
+			}
+			kept = append(kept, m)
+		}
+	}
+	for _, m := range largest {
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+// adaptedChildFeatures returns the Features this container's children contribute, after TraitAdaptationList has
+// excluded or deduplicated conflicting entries. Returns nil unless adaptsChildren() is true. Features aren't named
+// directly, so exclude/keep_largest rules match against fmt.Stringer's String() when a Feature implements it; rules
+// with a Name that can't be matched this way have no effect on Features. Unlike adaptedChildModifiers, keep_largest
+// here keeps whichever matching Feature is encountered first rather than the one with the largest magnitude: Feature
+// exposes no common, typed accessor for "how large" a bonus is across its different concrete kinds, so there's
+// nothing generic to compare. A feature-specific magnitude comparison could be added if that turns out to matter in
+// practice.
+func (t *Trait) adaptedChildFeatures() Features {
+	if !t.adaptsChildren() {
+		return nil
+	}
+	seenLargest := make(map[string]bool)
+	var kept Features
+	for _, child := range t.Children {
+		for _, one := range child.FeatureList() {
+			label := featureLabel(one)
+			if t.excludesByName(child.Name, label) {
+				continue
+			}
+			if t.keepsLargestByName(label) {
+				if seenLargest[label] {
+					continue
+				}
+				seenLargest[label] = true
+			}
+			kept = append(kept, one)
+		}
+	}
+	return kept
+}
+
+func featureLabel(f any) string {
+	if s, ok := f.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return ""
+}
+
+// adaptedPointsAdjustment returns the net change in points caused by excluding or deduplicating the flat, non-leveled
+// TraitModifiers (CostType tmcost.Points) this container's children contribute. Without this correction, a duplicate
+// flat-point modifier that TraitAdaptationList drops at the container level would still have been counted once
+// inside the child it came from, since each child computes its own AdjustedPoints() independently. Modifiers of any
+// other CostType (percentage, multiplier, expression) aren't corrected here: their point effect depends on the child
+// trait's own base points and can't be decomposed back out at this level. If TraitAdaptationList excludes or
+// deduplicates one of those
+// instead, this container's AdjustedPoints() can't account for it and is logged as unreliable rather than left
+// silently wrong.
+func (t *Trait) adaptedPointsAdjustment() fxp.Int {
+	if !t.adaptsChildren() {
+		return 0
+	}
+	var raw, adapted fxp.Int
+	rawNonPoints := 0
+	for _, child := range t.Children {
+		for _, m := range child.Modifiers {
+			if m.CostType == tmcost.Points {
+				raw += m.CostModifier()
+			} else {
+				rawNonPoints++
+			}
+		}
+	}
+	adaptedNonPoints := 0
+	for _, m := range t.adaptedChildModifiers() {
+		if m.CostType == tmcost.Points {
+			adapted += m.CostModifier()
+		} else {
+			adaptedNonPoints++
+		}
+	}
+	if rawNonPoints != adaptedNonPoints {
+		errs.Log(errs.New("TraitAdaptationList excluded or deduplicated a TraitModifier whose cost type isn't points; "+
+			"AdjustedPoints() can't correct for that and may be wrong"), "trait", t.Name)
+	}
+	return adapted - raw
+}
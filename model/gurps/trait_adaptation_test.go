@@ -0,0 +1,68 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package gurps
+
+import (
+	"testing"
+
+	"github.com/richardwilkes/gcs/v5/model/gurps/enums/container"
+	"github.com/richardwilkes/json"
+)
+
+func TestTraitAdaptationRule_RoundTripJSON(t *testing.T) {
+	original := []*TraitAdaptationRule{
+		{Action: TraitAdaptationExclude, Name: "Reduced Fatigue", From: "Ice Form"},
+		{Action: TraitAdaptationAlias, Name: "Skill Bonus", From: "Ice Form", To: "Elemental Form"},
+		{Action: TraitAdaptationKeepLargest, Name: "Striking ST Bonus"},
+	}
+	data, err := json.Marshal(&original)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded []*TraitAdaptationRule
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(decoded) != len(original) {
+		t.Fatalf("got %d rules, want %d", len(decoded), len(original))
+	}
+	for i, rule := range decoded {
+		if *rule != *original[i] {
+			t.Errorf("rule %d: got %+v, want %+v", i, rule, original[i])
+		}
+	}
+}
+
+// TestTraitContainerOnlyEditData_MigratesAbsentTraitAdaptationList covers loading a container Trait saved before
+// TraitAdaptationList existed: the field should come back nil rather than fail to load.
+func TestTraitContainerOnlyEditData_MigratesAbsentTraitAdaptationList(t *testing.T) {
+	var data TraitContainerOnlyEditData
+	if err := json.Unmarshal([]byte(`{}`), &data); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if data.TraitAdaptationList != nil {
+		t.Fatalf("expected a nil TraitAdaptationList for pre-existing data, got %v", data.TraitAdaptationList)
+	}
+}
+
+// TestFeatureList_MetaTraitKeepsOwnAndChildFeatures guards against the c67367a regression where FeatureList returned
+// only a MetaTrait/AlternativeAbilities container's adapted child Features, silently discarding its own.
+func TestFeatureList_MetaTraitKeepsOwnAndChildFeatures(t *testing.T) {
+	root := NewTrait(nil, nil, true)
+	root.ContainerType = container.MetaTrait
+	root.Features = make(Features, 1)
+	child := NewTrait(nil, root, false)
+	child.Features = make(Features, 1)
+	root.Children = append(root.Children, child)
+	if list := root.FeatureList(); len(list) != 2 {
+		t.Fatalf("expected FeatureList to include both the container's own Feature and its child's, got %d entries",
+			len(list))
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package gurps
+
+// invalidateCache marks this Trait's cached AllModifiers()/AdjustedPoints() results as stale and propagates the
+// invalidation to every ancestor, since a container's adjusted points and aggregated modifiers depend on its
+// children.
+func (t *Trait) invalidateCache() {
+	t.modifiersCacheValid = false
+	t.pointsCacheValid = false
+	if t.parent != nil {
+		t.parent.invalidateCache()
+	}
+}
+
+// invalidateCacheDownward marks this Trait's and every descendant's cached results as stale, for use when a change
+// to this Trait affects Enabled()/EffectivelyDisabled() for everything beneath it, and propagates upward as well,
+// since this Trait's own ancestors' aggregated points depend on it.
+func (t *Trait) invalidateCacheDownward() {
+	t.modifiersCacheValid = false
+	t.pointsCacheValid = false
+	for _, child := range t.Children {
+		child.invalidateCacheDownward()
+	}
+	if t.parent != nil {
+		t.parent.invalidateCache()
+	}
+}
+
+// SetDisabled sets whether this Trait is disabled, invalidating the cached AllModifiers()/AdjustedPoints() results
+// for this subtree and its ancestors, since Disabled is inherited by every descendant via EffectivelyDisabled().
+func (t *Trait) SetDisabled(disabled bool) {
+	if t.Disabled == disabled {
+		return
+	}
+	t.Disabled = disabled
+	t.invalidateCacheDownward()
+}
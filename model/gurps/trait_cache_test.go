@@ -0,0 +1,62 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package gurps
+
+import (
+	"testing"
+
+	"github.com/richardwilkes/gcs/v5/model/fxp"
+)
+
+func buildTraitWithChildrenForBench(childCount int) *Trait {
+	root := NewTrait(nil, nil, true)
+	for i := 0; i < childCount; i++ {
+		child := NewTrait(nil, root, false)
+		child.BasePoints = fxp.Twenty
+		root.Children = append(root.Children, child)
+	}
+	return root
+}
+
+// BenchmarkTrait_AdjustedPoints_Cached measures repeated AdjustedPoints() calls against an already-warmed cache, the
+// common case once a large library has finished loading.
+func BenchmarkTrait_AdjustedPoints_Cached(b *testing.B) {
+	root := buildTraitWithChildrenForBench(500)
+	root.AdjustedPoints() // warm the cache
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		root.AdjustedPoints()
+	}
+}
+
+// BenchmarkTrait_AdjustedPoints_Uncached measures the same workload with the cache invalidated before every call, to
+// show the cost invalidateCacheDownward's callers are trading away.
+func BenchmarkTrait_AdjustedPoints_Uncached(b *testing.B) {
+	root := buildTraitWithChildrenForBench(500)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		root.invalidateCacheDownward()
+		root.AdjustedPoints()
+	}
+}
+
+func TestInvalidateCacheDownward_InvalidatesDescendants(t *testing.T) {
+	root := buildTraitWithChildrenForBench(3)
+	root.AdjustedPoints()
+	root.invalidateCacheDownward()
+	if root.pointsCacheValid || root.modifiersCacheValid {
+		t.Fatal("expected invalidateCacheDownward to clear the root's own cache")
+	}
+	for _, child := range root.Children {
+		if child.pointsCacheValid || child.modifiersCacheValid {
+			t.Fatal("expected invalidateCacheDownward to clear every descendant's cache")
+		}
+	}
+}
@@ -64,6 +64,11 @@ type GeneralModifier interface {
 type TraitModifier struct {
 	TraitModifierData
 	Entity *Entity
+	owner  *Trait // the Trait this modifier (or its container ancestor) belongs to; used to resolve ExclusiveGroup and Requires
+	// evaluatingCostExpression guards against the unbounded recursion a CostExpression referencing "trait_points"
+	// would otherwise cause: evaluating it calls owner.AdjustedPoints(), which calls AllModifiers(), which calls back
+	// into this same modifier's CostModifier().
+	evaluatingCostExpression bool
 }
 
 // TraitModifierData holds the TraitModifier data that is written to disk.
@@ -92,6 +97,16 @@ type TraitModifierEditDataNonContainerOnly struct {
 	CostType tmcost.Type    `json:"cost_type,omitempty"`
 	Disabled bool           `json:"disabled,omitempty"`
 	Features Features       `json:"features,omitempty"`
+	// ExclusiveGroup, when non-empty, marks this modifier as mutually exclusive with every other modifier on the
+	// same trait that shares the same group name: enabling one disables the rest. Absent in older files, which have
+	// no exclusivity behavior, so it safely defaults to "".
+	ExclusiveGroup string `json:"exclusive_group,omitempty"`
+	// Requires lists the LocalIDs and/or ExclusiveGroup names of other modifiers on the same trait that must already
+	// be enabled before this one can be. Absent in older files, which have no prerequisites.
+	Requires []string `json:"requires,omitempty"`
+	// CostExpression holds a small formula to evaluate in place of Cost when CostType is tmcost.Expression. Absent
+	// in older files, which only ever used the numeric Cost.
+	CostExpression string `json:"cost_expression,omitempty"`
 }
 
 type traitModifierListData struct {
@@ -112,6 +127,7 @@ func NewTraitModifiersFromFile(fileSystem fs.FS, filePath string) ([]*TraitModif
 	if err := CheckVersion(data.Version); err != nil {
 		return nil, err
 	}
+	detectTraitModifierRequiresCycles(data.Rows)
 	return data.Rows, nil
 }
 
@@ -206,6 +222,7 @@ func (m *TraitModifier) CellData(columnID int, data *CellData) {
 			data.Type = cell.Toggle
 			data.Checked = m.Enabled()
 			data.Alignment = align.Middle
+			data.Tooltip = m.DisabledReason()
 		}
 	case TraitModifierDescriptionColumn:
 		data.Type = cell.Text
@@ -257,12 +274,27 @@ func (m *TraitModifier) SetOwningEntity(entity *Entity) {
 	}
 }
 
-// CostModifier returns the total cost modifier.
+// CostModifier returns the total cost modifier. If CostType is tmcost.Expression, CostExpression is evaluated in
+// place of Cost; a parse error falls back to the numeric Cost and is logged. If CostExpression references
+// "trait_points" and that evaluation recurses back into this same modifier (i.e. it's trying to cost itself against
+// the owning trait's own adjusted points), the recursive call falls back to the numeric Cost instead of evaluating
+// the expression again, so the result is well-defined instead of stack-overflowing.
 func (m *TraitModifier) CostModifier() fxp.Int {
+	cost := m.Cost
+	if m.CostType == tmcost.Expression && m.CostExpression != "" && !m.evaluatingCostExpression {
+		m.evaluatingCostExpression = true
+		value, err := m.evaluateCostExpression()
+		m.evaluatingCostExpression = false
+		if err != nil {
+			errs.Log(err, "expression", m.CostExpression)
+		} else {
+			cost = value
+		}
+	}
 	if m.Levels > 0 {
-		return m.Cost.Mul(m.Levels)
+		return cost.Mul(m.Levels)
 	}
-	return m.Cost
+	return cost
 }
 
 // IsLeveled returns true if this TraitModifier is leveled.
@@ -336,6 +368,8 @@ func (m *TraitModifier) CostDescription() string {
 		base = m.Cost.StringWithSign()
 	case tmcost.Multiplier:
 		return m.CostType.String() + m.Cost.String()
+	case tmcost.Expression:
+		base = m.CostModifier().StringWithSign()
 	default:
 		errs.Log(errs.New("unknown cost type"), "type", int(m.CostType))
 		base = m.Cost.StringWithSign() + tmcost.Percentage.String()
@@ -351,6 +385,7 @@ func (m *TraitModifier) FillWithNameableKeys(keyMap map[string]string) {
 	if !m.Container() && m.Enabled() {
 		Extract(m.Name, keyMap)
 		Extract(m.LocalNotes, keyMap)
+		Extract(m.CostExpression, keyMap)
 		for _, one := range m.Features {
 			one.FillWithNameableKeys(keyMap)
 		}
@@ -362,6 +397,7 @@ func (m *TraitModifier) ApplyNameableKeys(keyMap map[string]string) {
 	if !m.Container() && m.Enabled() {
 		m.Name = Apply(m.Name, keyMap)
 		m.LocalNotes = Apply(m.LocalNotes, keyMap)
+		m.CostExpression = Apply(m.CostExpression, keyMap)
 		for _, one := range m.Features {
 			one.ApplyNameableKeys(keyMap)
 		}
@@ -370,14 +406,27 @@ func (m *TraitModifier) ApplyNameableKeys(keyMap map[string]string) {
 
 // Enabled returns true if this node is enabled.
 func (m *TraitModifier) Enabled() bool {
-	return !m.Disabled || m.Container()
+	return m.Container() || (!m.Disabled && m.RequirementsSatisfied())
 }
 
-// SetEnabled makes the node enabled, if possible.
+// SetEnabled makes the node enabled, if possible. Enabling refuses to take effect if this modifier's Requires aren't
+// currently satisfied. Successfully enabling a modifier with an ExclusiveGroup disables every other modifier on the
+// same trait that shares that group.
 func (m *TraitModifier) SetEnabled(enabled bool) {
-	if !m.Container() {
-		m.Disabled = !enabled
+	if m.Container() {
+		return
+	}
+	if !enabled {
+		m.Disabled = true
+		m.invalidateOwnerCache()
+		return
+	}
+	if !m.RequirementsSatisfied() {
+		return
 	}
+	m.Disabled = false
+	m.disableExclusiveSiblings()
+	m.invalidateOwnerCache()
 }
 
 // Kind returns the kind of data.
@@ -395,6 +444,9 @@ func (m *TraitModifier) ClearUnusedFieldsForType() {
 		m.Levels = 0
 		m.Affects = 0
 		m.Features = nil
+		m.ExclusiveGroup = ""
+		m.Requires = nil
+		m.CostExpression = ""
 	}
 }
 
@@ -415,4 +467,5 @@ func (m *TraitModifier) copyFrom(other *TraitModifier) {
 	m.TraitModifierData = other.TraitModifierData
 	m.Tags = txt.CloneStringSlice(m.Tags)
 	m.Features = other.Features.Clone()
+	m.invalidateOwnerCache()
 }
@@ -0,0 +1,58 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package gurps
+
+import (
+	"fmt"
+
+	"github.com/richardwilkes/gcs/v5/model/fxp"
+	"github.com/richardwilkes/toolbox/eval"
+)
+
+// traitModifierCostResolver resolves the variables a TraitModifier's CostExpression may reference: the modifier's
+// own level and enabled state, the owning trait's adjusted points, and, for anything else, whatever the owning
+// Entity itself knows how to resolve (e.g. attribute values), so the same expressions an attribute bonus would use
+// also work here.
+type traitModifierCostResolver struct {
+	modifier *TraitModifier
+}
+
+// ResolveVariable implements eval.VariableResolver.
+func (r *traitModifierCostResolver) ResolveVariable(variableName string) string {
+	switch variableName {
+	case "level":
+		return r.modifier.Levels.String()
+	case "enabled":
+		if r.modifier.Enabled() {
+			return "1"
+		}
+		return "0"
+	case "trait_points":
+		if r.modifier.owner != nil {
+			return r.modifier.owner.AdjustedPoints().String()
+		}
+		return "0"
+	default:
+		if resolver, ok := any(r.modifier.Entity).(eval.VariableResolver); ok {
+			return resolver.ResolveVariable(variableName)
+		}
+		return "0"
+	}
+}
+
+// evaluateCostExpression evaluates CostExpression and returns the resulting cost, in points, before any level
+// multiplication is applied.
+func (m *TraitModifier) evaluateCostExpression() (fxp.Int, error) {
+	result, err := eval.NewEvaluator(&traitModifierCostResolver{modifier: m}, true).Evaluate(m.CostExpression)
+	if err != nil {
+		return 0, err
+	}
+	return fxp.FromStringForced(fmt.Sprint(result)), nil
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package gurps
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/richardwilkes/toolbox/errs"
+	"github.com/richardwilkes/toolbox/i18n"
+	"github.com/richardwilkes/toolbox/tid"
+)
+
+// setOwnerTrait records which Trait this modifier (and its children, if it is a container) belongs to, so
+// ExclusiveGroup and Requires can be resolved against the trait's full modifier tree.
+func (m *TraitModifier) setOwnerTrait(t *Trait) {
+	m.owner = t
+	if m.Container() {
+		for _, child := range m.Children {
+			child.setOwnerTrait(t)
+		}
+	}
+}
+
+// invalidateOwnerCache invalidates the owning Trait's cached AllModifiers()/AdjustedPoints() results, if the owner is
+// known, since enabling or disabling a modifier changes both. This has to propagate downward as well as upward: a
+// container's modifiers are inherited by every descendant via Trait.AllModifiers(), so a descendant's cached results
+// are just as stale as the owner's and its ancestors' when one of the owner's modifiers is toggled.
+func (m *TraitModifier) invalidateOwnerCache() {
+	if m.owner != nil {
+		m.owner.invalidateCacheDownward()
+	}
+}
+
+// RequirementsSatisfied returns true if every entry in Requires names either a modifier (by LocalID) or an exclusive
+// group with at least one member, elsewhere in the same owning trait's modifier tree, that isn't switched off by its
+// Disabled flag. The Requires of those other modifiers aren't checked. Returns true if there are no requirements, or
+// if the owning trait isn't known yet (e.g. before SetOwningEntity has run).
+func (m *TraitModifier) RequirementsSatisfied() bool {
+	if len(m.Requires) == 0 || m.owner == nil {
+		return true
+	}
+	for _, req := range m.Requires {
+		if !m.requirementMet(req) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *TraitModifier) requirementMet(req string) bool {
+	met := false
+	Traverse(func(other *TraitModifier) bool {
+		if other == m || other.Container() || other.Disabled {
+			return false
+		}
+		if string(other.LocalID) == req || other.ExclusiveGroup == req {
+			met = true
+			return true
+		}
+		return false
+	}, true, true, m.owner.Modifiers...)
+	return met
+}
+
+// disableExclusiveSiblings disables every other modifier in the owning trait's modifier tree that shares this
+// modifier's ExclusiveGroup.
+func (m *TraitModifier) disableExclusiveSiblings() {
+	if m.ExclusiveGroup == "" || m.owner == nil {
+		return
+	}
+	Traverse(func(other *TraitModifier) bool {
+		if other != m && !other.Container() && other.ExclusiveGroup == m.ExclusiveGroup {
+			other.Disabled = true
+		}
+		return false
+	}, true, true, m.owner.Modifiers...)
+}
+
+// conflictingExclusiveSibling returns the other enabled modifier sharing this one's ExclusiveGroup, if any.
+func (m *TraitModifier) conflictingExclusiveSibling() *TraitModifier {
+	if m.ExclusiveGroup == "" || m.owner == nil {
+		return nil
+	}
+	var found *TraitModifier
+	Traverse(func(other *TraitModifier) bool {
+		if other != m && !other.Container() && other.ExclusiveGroup == m.ExclusiveGroup && !other.Disabled {
+			found = other
+			return true
+		}
+		return false
+	}, true, true, m.owner.Modifiers...)
+	return found
+}
+
+// DisabledReason explains why this modifier is currently unavailable, for display in the UI (e.g. as the tooltip on
+// its enabled checkbox). Returns "" when the modifier is enabled, is a container, or was simply turned off directly
+// with no unmet prerequisite or conflict to explain.
+func (m *TraitModifier) DisabledReason() string {
+	if m.Container() || m.Enabled() {
+		return ""
+	}
+	if !m.RequirementsSatisfied() {
+		return i18n.Text("Requires: ") + strings.Join(m.Requires, ", ")
+	}
+	if other := m.conflictingExclusiveSibling(); other != nil {
+		return fmt.Sprintf(i18n.Text("Mutually exclusive with %q"), other.Name)
+	}
+	return ""
+}
+
+// detectTraitModifierRequiresCycles looks for cycles amongst the LocalID-based Requires of the given modifiers
+// (group-name requirements aren't included, since a group isn't a single node in the dependency graph) and logs one
+// if found. The file is still loaded either way; a cycle means that once every modifier in it is disabled, SetEnabled
+// can't turn any of them back on, which is reported so the data can be fixed.
+func detectTraitModifierRequiresCycles(modifiers []*TraitModifier) {
+	byID := make(map[tid.TID]*TraitModifier)
+	Traverse(func(m *TraitModifier) bool {
+		byID[m.LocalID] = m
+		return false
+	}, true, true, modifiers...)
+	state := make(map[tid.TID]int) // 0 = unvisited, 1 = in progress, 2 = done
+	var visit func(id tid.TID, path []tid.TID) bool
+	visit = func(id tid.TID, path []tid.TID) bool {
+		switch state[id] {
+		case 1:
+			errs.Log(errs.New("trait modifier Requires cycle detected"), "path", fmt.Sprint(path))
+			return true
+		case 2:
+			return false
+		}
+		state[id] = 1
+		if m, ok := byID[id]; ok {
+			for _, req := range m.Requires {
+				reqID := tid.TID(req)
+				if _, known := byID[reqID]; known {
+					if visit(reqID, append(path, reqID)) {
+						return true
+					}
+				}
+			}
+		}
+		state[id] = 2
+		return false
+	}
+	for id := range byID {
+		if state[id] == 0 {
+			visit(id, []tid.TID{id})
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 1998-2024 by Richard A. Wilkes. All rights reserved.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, version 2.0. If a copy of the MPL was not distributed with
+// this file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// This Source Code Form is "Incompatible With Secondary Licenses", as
+// defined by the Mozilla Public License, version 2.0.
+
+package gurps
+
+import "testing"
+
+func TestDetectTraitModifierRequiresCycles_SelfAndMutualCyclesDontHang(t *testing.T) {
+	self := NewTraitModifier(nil, nil, false)
+	self.Requires = []string{string(self.LocalID)}
+
+	a := NewTraitModifier(nil, nil, false)
+	b := NewTraitModifier(nil, nil, false)
+	a.Requires = []string{string(b.LocalID)}
+	b.Requires = []string{string(a.LocalID)}
+
+	// Neither a direct self-cycle nor a mutual cycle should recurse forever; this test merely needs to return.
+	detectTraitModifierRequiresCycles([]*TraitModifier{self, a, b})
+}
+
+func TestRequirementsSatisfied_NoOwnerIsAlwaysSatisfied(t *testing.T) {
+	m := NewTraitModifier(nil, nil, false)
+	m.Requires = []string{"some-requirement-id"}
+	if !m.RequirementsSatisfied() {
+		t.Fatal("expected RequirementsSatisfied to be true before the modifier has an owning trait")
+	}
+}